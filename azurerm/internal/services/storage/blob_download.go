@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+	"github.com/tombuildsstuff/giovanni/storage/2018-11-09/blob/blobs"
+)
+
+const defaultDownloadRangeSize int64 = 4 * 1024 * 1024
+
+// BlobDownload is the download-side counterpart to BlobUpload: it materialises a
+// blob to a local file via parallel ranged GETs, coordinated the same way the
+// page-blob uploader coordinates parallel PutPageUpdate calls.
+type BlobDownload struct {
+	Client *blobs.Client
+
+	AccountName   string
+	BlobName      string
+	ContainerName string
+
+	// Destination is the local file path the blob is downloaded to.
+	Destination string
+
+	// RangeSize is the size, in bytes, of each ranged GET. Defaults to 4 MiB.
+	RangeSize int64
+
+	Parallelism int
+}
+
+type blobDownloadRange struct {
+	offset int64
+	length int64
+}
+
+// splitDownloadRanges divides a blob of blobSize bytes into fixed-size ranges
+// (the last of which may be shorter), skipping any range already fully present
+// in an existingSize-byte file on disk so a previously interrupted download can
+// be resumed.
+func splitDownloadRanges(blobSize int64, rangeSize int64, existingSize int64) []blobDownloadRange {
+	var ranges []blobDownloadRange
+	for offset := int64(0); offset < blobSize; offset += rangeSize {
+		length := rangeSize
+		if offset+length > blobSize {
+			length = blobSize - offset
+		}
+
+		if existingSize >= offset+length {
+			// already on disk from a previous run - nothing to do
+			continue
+		}
+
+		ranges = append(ranges, blobDownloadRange{offset: offset, length: length})
+	}
+
+	return ranges
+}
+
+// Download fetches the blob to Destination using parallel ranged GETs. If
+// Destination already exists, any range whose bytes are already present on disk
+// (i.e. the file is at least as long as the end of that range) is skipped, so a
+// previously interrupted download can be resumed by calling Download again.
+func (bd BlobDownload) Download(ctx context.Context) error {
+	props, err := bd.Client.GetProperties(ctx, bd.AccountName, bd.ContainerName, bd.BlobName, blobs.GetPropertiesInput{})
+	if err != nil {
+		return fmt.Errorf("Error retrieving properties for blob %q: %s", bd.BlobName, err)
+	}
+
+	file, err := os.OpenFile(bd.Destination, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("Error opening destination file %q: %s", bd.Destination, err)
+	}
+	defer file.Close()
+
+	existingSize := int64(0)
+	if info, statErr := file.Stat(); statErr == nil {
+		existingSize = info.Size()
+	}
+
+	// trim any leftover tail beyond the blob's current length - from a stale file
+	// at this path, or the blob having shrunk since a previous download - so the
+	// destination never ends up longer than the blob.
+	if existingSize > props.ContentLength {
+		if err := file.Truncate(props.ContentLength); err != nil {
+			return fmt.Errorf("Error truncating destination file %q to %d bytes: %s", bd.Destination, props.ContentLength, err)
+		}
+	}
+
+	if err := bd.downloadRanges(ctx, file, props.ContentLength, props.ETag, existingSize); err != nil {
+		return fmt.Errorf("Error downloading blob %q to %q: %s", bd.BlobName, bd.Destination, err)
+	}
+
+	return nil
+}
+
+func (bd BlobDownload) downloadRanges(ctx context.Context, file io.WriterAt, blobSize int64, etag string, existingSize int64) error {
+	rangeSize := bd.RangeSize
+	if rangeSize <= 0 {
+		rangeSize = defaultDownloadRangeSize
+	}
+
+	ranges := splitDownloadRanges(blobSize, rangeSize, existingSize)
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	workerCount := workerCountFor(bd.Parallelism)
+
+	jobs := make(chan blobDownloadRange, len(ranges))
+	errors := make(chan error, len(ranges))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(ranges))
+
+	for _, r := range ranges {
+		jobs <- r
+	}
+	close(jobs)
+
+	for i := 0; i < workerCount; i++ {
+		go bd.blobDownloadWorker(ctx, blobDownloadContext{
+			file:   file,
+			etag:   etag,
+			jobs:   jobs,
+			errors: errors,
+			wg:     wg,
+		})
+	}
+
+	wg.Wait()
+
+	if len(errors) > 0 {
+		return <-errors
+	}
+
+	return nil
+}
+
+type blobDownloadContext struct {
+	file   io.WriterAt
+	etag   string
+	jobs   chan blobDownloadRange
+	errors chan error
+	wg     *sync.WaitGroup
+}
+
+func (bd BlobDownload) blobDownloadWorker(ctx context.Context, downloadCtx blobDownloadContext) {
+	for r := range downloadCtx.jobs {
+		if ctx.Err() != nil {
+			downloadCtx.errors <- ctx.Err()
+			downloadCtx.wg.Done()
+			continue
+		}
+
+		input := blobs.GetInput{
+			StartByte: utils.Int64(r.offset),
+			EndByte:   utils.Int64(r.offset + r.length - 1),
+			IfMatch:   utils.String(downloadCtx.etag),
+		}
+
+		result, err := bd.Client.Get(ctx, bd.AccountName, bd.ContainerName, bd.BlobName, input)
+		if err != nil {
+			downloadCtx.errors <- fmt.Errorf("Error fetching range %d-%d: %s", r.offset, r.offset+r.length-1, err)
+			downloadCtx.wg.Done()
+			continue
+		}
+
+		if _, err := downloadCtx.file.WriteAt(result.Contents, r.offset); err != nil {
+			downloadCtx.errors <- fmt.Errorf("Error writing range %d-%d to destination: %s", r.offset, r.offset+r.length-1, err)
+			downloadCtx.wg.Done()
+			continue
+		}
+
+		downloadCtx.wg.Done()
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import "testing"
+
+func TestSplitDownloadRanges(t *testing.T) {
+	cases := []struct {
+		name         string
+		blobSize     int64
+		rangeSize    int64
+		existingSize int64
+		wantOffsets  []int64
+	}{
+		{name: "exact multiple", blobSize: 20, rangeSize: 10, existingSize: 0, wantOffsets: []int64{0, 10}},
+		{name: "short final range", blobSize: 25, rangeSize: 10, existingSize: 0, wantOffsets: []int64{0, 10, 20}},
+		{name: "empty blob", blobSize: 0, rangeSize: 10, existingSize: 0, wantOffsets: nil},
+		{name: "fully resumed", blobSize: 20, rangeSize: 10, existingSize: 20, wantOffsets: nil},
+		{name: "partially resumed", blobSize: 20, rangeSize: 10, existingSize: 10, wantOffsets: []int64{10}},
+		{name: "existing short of a full range", blobSize: 20, rangeSize: 10, existingSize: 5, wantOffsets: []int64{0, 10}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ranges := splitDownloadRanges(tc.blobSize, tc.rangeSize, tc.existingSize)
+
+			if len(ranges) != len(tc.wantOffsets) {
+				t.Fatalf("got %d ranges, want %d", len(ranges), len(tc.wantOffsets))
+			}
+			for i, r := range ranges {
+				if r.offset != tc.wantOffsets[i] {
+					t.Fatalf("range %d offset = %d, want %d", i, r.offset, tc.wantOffsets[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitDownloadRangesCoverWholeBlobWithoutOverlap(t *testing.T) {
+	const blobSize = 37
+	const rangeSize = 10
+
+	ranges := splitDownloadRanges(blobSize, rangeSize, 0)
+
+	var covered int64
+	for i, r := range ranges {
+		if r.offset != covered {
+			t.Fatalf("range %d offset = %d, want %d (ranges must be contiguous)", i, r.offset, covered)
+		}
+		covered += r.length
+	}
+
+	if covered != blobSize {
+		t.Fatalf("ranges cover %d bytes, want %d", covered, blobSize)
+	}
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/tombuildsstuff/giovanni/storage/2018-11-09/file/files"
+)
+
+// SourceProvider supplies the bytes to upload for a blob, decoupling BlobUpload
+// from the assumption that its source is always a path on the local disk.
+type SourceProvider interface {
+	// Open returns a seekable, closeable stream of the source content and its
+	// total size. The caller is responsible for closing the returned stream. ctx
+	// governs any network calls Open itself makes (e.g. fetching an Azure Files
+	// attribute/contents); URLSourceProvider's returned response body is also tied
+	// to ctx, while the other providers' streams do not use it.
+	Open(ctx context.Context) (io.ReadSeekCloser, int64, error)
+}
+
+// FileSourceProvider reads from a path on the local filesystem.
+type FileSourceProvider struct {
+	Path string
+}
+
+func (p FileSourceProvider) Open(ctx context.Context) (io.ReadSeekCloser, int64, error) {
+	file, err := os.Open(p.Path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error opening %q: %s", p.Path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("Could not stat file %q: %s", p.Path, err)
+	}
+
+	return file, info.Size(), nil
+}
+
+// BufferSourceProvider reads from an in-memory buffer, e.g. content rendered by
+// a Terraform template or assembled by the caller rather than staged to disk.
+type BufferSourceProvider struct {
+	Data []byte
+}
+
+func (p BufferSourceProvider) Open(ctx context.Context) (io.ReadSeekCloser, int64, error) {
+	return nopCloser{bytes.NewReader(p.Data)}, int64(len(p.Data)), nil
+}
+
+// URLSourceProvider streams content from an HTTP(S) URL. Unlike `copy`
+// (`CopyAndWait`), which asks the storage service to fetch the URL itself, this
+// streams the response body through the provider's own connection - useful when
+// the URL isn't reachable by the storage account, e.g. it's behind auth this
+// process holds but Azure doesn't.
+type URLSourceProvider struct {
+	URL string
+
+	// Client is the HTTP client used to fetch URL. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (p URLSourceProvider) Open(ctx context.Context) (io.ReadSeekCloser, int64, error) {
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error building request for %q: %s", p.URL, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error fetching %q: %s", p.URL, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("Error fetching %q: unexpected status %q", p.URL, resp.Status)
+	}
+
+	// the response body can only be read forward once, so it's exposed as a
+	// non-seekable stream - callers needing ReaderAt semantics (e.g. the
+	// page-blob splitter) fall back to buffering it into memory first.
+	return nonSeekableReadCloser{resp.Body}, resp.ContentLength, nil
+}
+
+// AzureFileShareSourceProvider reads a file out of an Azure Files share.
+type AzureFileShareSourceProvider struct {
+	Client *files.Client
+
+	AccountName string
+	ShareName   string
+	Path        string
+}
+
+func (p AzureFileShareSourceProvider) Open(ctx context.Context) (io.ReadSeekCloser, int64, error) {
+	attrs, err := p.Client.GetProperties(ctx, p.AccountName, p.ShareName, p.Path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error retrieving properties for file %q in share %q: %s", p.Path, p.ShareName, err)
+	}
+
+	resp, err := p.Client.GetFile(ctx, p.AccountName, p.ShareName, p.Path, files.GetFileInput{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error reading file %q from share %q: %s", p.Path, p.ShareName, err)
+	}
+
+	// resp.Contents is already fully read into memory, so it can be handed back
+	// as a ReaderAt directly rather than wrapped as non-seekable and buffered a
+	// second time by readerAtFor.
+	return nopCloser{bytes.NewReader(resp.Contents)}, attrs.ContentLength, nil
+}
+
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error { return nil }
+
+// nonSeekableReadCloser wraps a stream that only supports forward reads (e.g. an
+// HTTP response body) so it still satisfies io.ReadSeekCloser; Seek always fails.
+// It does not implement io.ReaderAt, which is how readerAtFor detects it needs to
+// buffer instead.
+type nonSeekableReadCloser struct {
+	io.ReadCloser
+}
+
+func (nonSeekableReadCloser) Seek(offset int64, whence int) (int64, error) {
+	return 0, fmt.Errorf("this source does not support seeking")
+}
+
+// readerAtFor returns an io.ReaderAt over stream plus its true size, buffering
+// the entire stream into memory first if it doesn't already support random
+// access (e.g. a streamed HTTP source) or if the caller-supplied
+// size isn't trustworthy - e.g. an HTTP response with no Content-Length reports
+// size as -1, which must never be propagated on as the blob's length.
+func readerAtFor(stream io.ReadSeekCloser, size int64) (io.ReaderAt, int64, error) {
+	if ra, ok := stream.(io.ReaderAt); ok && size >= 0 {
+		return ra, size, nil
+	}
+
+	buf, err := ioutil.ReadAll(stream)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error buffering source into memory: %s", err)
+	}
+
+	return bytes.NewReader(buf), int64(len(buf)), nil
+}
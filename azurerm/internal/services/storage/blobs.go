@@ -3,9 +3,13 @@ package storage
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"hash/crc64"
 	"io"
-	"os"
+	"log"
 	"runtime"
 	"strings"
 	"sync"
@@ -31,8 +35,172 @@ type BlobUpload struct {
 	Size        int
 	Source      string
 	SourceUri   string
+
+	// SourceProvider, when set, takes precedence over Source and supplies the
+	// content to upload from somewhere other than a local file path - an
+	// in-memory buffer, an HTTP(S) URL, or an Azure Files share.
+	SourceProvider SourceProvider
+
+	// MaxBlobSize caps the total size an append blob may grow to, enforced via the
+	// max-size conditional header so a re-run can't silently append past the limit.
+	MaxBlobSize int64
+
+	// MaxBlockCount caps the number of AppendBlock calls a single upload of an
+	// append blob makes; it is checked client-side before each call, and blocks
+	// already on the blob from a previous run are not counted. Re-run safety comes
+	// from the append-position conditional header, which every AppendBlock sets.
+	MaxBlockCount int
+
+	// BlockSize is the size of each staged block when uploading a block blob, in
+	// bytes. Defaults to 4 MiB and can be raised up to 100 MiB.
+	BlockSize int64
+
+	// Attempts is the total number of times a single block/page write is tried
+	// (with exponential backoff between tries) before the upload is considered
+	// failed. Zero or less means a single try.
+	Attempts int
+
+	// VerifyContent turns on client-side integrity checking of each staged
+	// block/page - Azure validates the checksum against the bytes it received and
+	// rejects the write on mismatch. Supported values are "md5" and "crc64"; empty
+	// disables verification.
+	VerifyContent string
+
+	// Progress, if set, is invoked (under a mutex) as each block/page finishes
+	// uploading, reporting the cumulative bytes transferred against the total.
+	Progress func(bytesTransferred, totalBytes int64)
+
+	// AccessTier is the tier to create the blob with - Hot/Cool/Archive for a
+	// block blob, or P4..P80 for a page blob.
+	//
+	// NOTE: not yet exposed as an azurerm_storage_blob resource argument - callers
+	// of this package can set it directly, but there's no Terraform-config path to
+	// it or to UpdateAccessTier below until the resource schema is updated.
+	AccessTier string
+
+	// EncryptionScope is the name of a predefined encryption scope to encrypt the
+	// blob's content with. It is applied to block and page blobs only; append
+	// blobs are created and appended to without it, as they are without
+	// CustomerProvidedKey.
+	EncryptionScope string
+
+	// CustomerProvidedKey supplies an SSE-C (customer-provided) encryption key to
+	// use instead of a Storage-managed or Key Vault key.
+	CustomerProvidedKey *CustomerProvidedKey
+}
+
+// CustomerProvidedKey is an SSE-C key supplied by the caller on every block/page
+// write, rather than relying on a key that Azure Storage manages itself.
+type CustomerProvidedKey struct {
+	Key       string
+	KeySHA256 string
+	Algorithm string
+}
+
+// applyEncryption populates the encryption-scope and customer-provided-key
+// fields shared by the various Put* inputs.
+func (sbu BlobUpload) applyEncryption(scope **string, key **string, keySHA256 **string, algorithm **string) {
+	if sbu.EncryptionScope != "" {
+		*scope = utils.String(sbu.EncryptionScope)
+	}
+
+	if sbu.CustomerProvidedKey != nil {
+		*key = utils.String(sbu.CustomerProvidedKey.Key)
+		*keySHA256 = utils.String(sbu.CustomerProvidedKey.KeySHA256)
+		*algorithm = utils.String(sbu.CustomerProvidedKey.Algorithm)
+	}
+}
+
+// UpdateAccessTier changes an existing blob's access tier via SetBlobTier,
+// rather than the resource having to recreate the blob when only its tier
+// changes between applies.
+func (sbu BlobUpload) UpdateAccessTier(ctx context.Context) error {
+	if sbu.AccessTier == "" {
+		return nil
+	}
+
+	input := blobs.SetBlobTierInput{
+		AccessTier: blobs.AccessTier(sbu.AccessTier),
+	}
+	if _, err := sbu.Client.SetBlobTier(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, input); err != nil {
+		return fmt.Errorf("Error SetBlobTier: %s", err)
+	}
+
+	return nil
+}
+
+const (
+	verifyContentMD5   = "md5"
+	verifyContentCRC64 = "crc64"
+)
+
+// progressTracker accumulates bytes transferred across concurrent workers and
+// reports them via BlobUpload.Progress, logging at 10% intervals so long
+// uploads show up in Terraform's debug log even without a Progress callback.
+type progressTracker struct {
+	mu               sync.Mutex
+	transferred      int64
+	total            int64
+	lastLoggedDecile int
+	callback         func(bytesTransferred, totalBytes int64)
 }
 
+func newProgressTracker(total int64, callback func(bytesTransferred, totalBytes int64)) *progressTracker {
+	return &progressTracker{total: total, callback: callback, lastLoggedDecile: -1}
+}
+
+func (pt *progressTracker) add(n int64) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	pt.transferred += n
+
+	if pt.callback != nil {
+		pt.callback(pt.transferred, pt.total)
+	}
+
+	if pt.total > 0 {
+		decile := int(pt.transferred * 10 / pt.total)
+		if decile > pt.lastLoggedDecile {
+			pt.lastLoggedDecile = decile
+			log.Printf("[DEBUG] Uploaded %d/%d bytes (%d%%)", pt.transferred, pt.total, decile*10)
+		}
+	}
+}
+
+// hasSource reports whether this upload has content to read (as opposed to
+// creating an empty blob).
+func (sbu BlobUpload) hasSource() bool {
+	return sbu.SourceProvider != nil || sbu.Source != ""
+}
+
+// resolveSource returns the configured SourceProvider, falling back to a
+// FileSourceProvider wrapping Source for backwards compatibility.
+func (sbu BlobUpload) resolveSource() SourceProvider {
+	if sbu.SourceProvider != nil {
+		return sbu.SourceProvider
+	}
+
+	return FileSourceProvider{Path: sbu.Source}
+}
+
+// sourceLabel is a human-readable description of the source, used in error
+// messages - the local path where there is one, or a generic description for
+// other source providers.
+func (sbu BlobUpload) sourceLabel() string {
+	if sbu.Source != "" {
+		return sbu.Source
+	}
+
+	return "source"
+}
+
+// Create dispatches to the upload/create path for sbu.BlobType.
+//
+// NOTE: "append" is handled here at the storage-package level only - nothing in
+// the azurerm_storage_blob resource schema currently sets blob_type = "append",
+// so this path is only reachable from direct callers of this package, not from
+// Terraform configuration.
 func (sbu BlobUpload) Create(ctx context.Context) error {
 	if sbu.SourceUri != "" {
 		return sbu.copy(ctx)
@@ -40,10 +208,8 @@ func (sbu BlobUpload) Create(ctx context.Context) error {
 
 	blobType := strings.ToLower(sbu.BlobType)
 
-	// TODO: new feature for 'append' blobs?
-
 	if blobType == "block" {
-		if sbu.Source != "" {
+		if sbu.hasSource() {
 			return sbu.uploadBlockBlob(ctx)
 		}
 
@@ -51,13 +217,21 @@ func (sbu BlobUpload) Create(ctx context.Context) error {
 	}
 
 	if blobType == "page" {
-		if sbu.Source != "" {
+		if sbu.hasSource() {
 			return sbu.uploadPageBlob(ctx)
 		}
 
 		return sbu.createEmptyPageBlob(ctx)
 	}
 
+	if blobType == "append" {
+		if sbu.hasSource() {
+			return sbu.uploadAppendBlob(ctx)
+		}
+
+		return sbu.createEmptyAppendBlob(ctx)
+	}
+
 	return fmt.Errorf("Unsupported Blob Type: %q", blobType)
 }
 
@@ -78,6 +252,11 @@ func (sbu BlobUpload) createEmptyBlockBlob(ctx context.Context) error {
 		ContentType: utils.String(sbu.ContentType),
 		MetaData:    sbu.MetaData,
 	}
+	if sbu.AccessTier != "" {
+		input.AccessTier = blobs.AccessTier(sbu.AccessTier)
+	}
+	sbu.applyEncryption(&input.EncryptionScope, &input.EncryptionKey, &input.EncryptionKeySHA256, &input.EncryptionAlgorithm)
+
 	if _, err := sbu.Client.PutBlockBlob(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, input); err != nil {
 		return fmt.Errorf("Error PutBlockBlob: %s", err)
 	}
@@ -85,24 +264,286 @@ func (sbu BlobUpload) createEmptyBlockBlob(ctx context.Context) error {
 	return nil
 }
 
+const (
+	defaultBlockSize int64 = 4 * 1024 * 1024
+	maxBlockSize     int64 = 100 * 1024 * 1024
+)
+
 func (sbu BlobUpload) uploadBlockBlob(ctx context.Context) error {
-	file, err := os.Open(sbu.Source)
+	stream, fileSize, err := sbu.resolveSource().Open(ctx)
 	if err != nil {
-		return fmt.Errorf("Error opening: %s", err)
+		return fmt.Errorf("Error opening %q: %s", sbu.sourceLabel(), err)
 	}
-	defer file.Close()
+	defer stream.Close()
 
-	input := blobs.PutBlockBlobInput{
+	file, fileSize, err := readerAtFor(stream, fileSize)
+	if err != nil {
+		return fmt.Errorf("Error preparing %q for upload: %s", sbu.sourceLabel(), err)
+	}
+
+	blockSize := sbu.BlockSize
+	if blockSize <= 0 {
+		blockSize = defaultBlockSize
+	}
+	if blockSize > maxBlockSize {
+		return fmt.Errorf("`BlockSize` of %d bytes exceeds the maximum of %d bytes", blockSize, maxBlockSize)
+	}
+
+	blockIDs, err := sbu.uploadBlocks(ctx, file, fileSize, blockSize)
+	if err != nil {
+		return fmt.Errorf("Error uploading blocks for %q: %s", sbu.sourceLabel(), err)
+	}
+
+	listInput := blobs.PutBlockListInput{
+		BlockIDs:    blockIDs,
 		ContentType: utils.String(sbu.ContentType),
 		MetaData:    sbu.MetaData,
 	}
-	if err := sbu.Client.PutBlockBlobFromFile(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, file, input); err != nil {
-		return fmt.Errorf("Error PutBlockBlobFromFile: %s", err)
+	if sbu.AccessTier != "" {
+		listInput.AccessTier = blobs.AccessTier(sbu.AccessTier)
+	}
+	sbu.applyEncryption(&listInput.EncryptionScope, &listInput.EncryptionKey, &listInput.EncryptionKeySHA256, &listInput.EncryptionAlgorithm)
+
+	if sbu.VerifyContent != "" {
+		// Set as the blob's Content-MD5 metadata for downstream consumers. This is
+		// not itself an integrity check: PutBlockList stores whatever Content-MD5 it's
+		// given rather than recomputing it from the committed blocks, so comparing it
+		// back against what we just sent would always "pass". The actual integrity
+		// guarantee for this upload comes from the per-block Content-MD5/CRC64 checks
+		// in putBlockWithRetry, which Azure does validate against the transmitted bytes.
+		wholeBlobMD5, err := md5OfReaderAt(file, fileSize)
+		if err != nil {
+			return fmt.Errorf("Error computing whole-blob MD5 for %q: %s", sbu.sourceLabel(), err)
+		}
+		listInput.ContentMD5 = utils.String(wholeBlobMD5)
+	}
+
+	if _, err := sbu.Client.PutBlockList(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, listInput); err != nil {
+		return fmt.Errorf("Error PutBlockList: %s", err)
 	}
 
 	return nil
 }
 
+// storageBlobBlock describes a single fixed-size chunk of the source file, staged
+// under a deterministic block ID so that re-staging the same offset on a retry (or
+// a subsequent apply) produces an identical block ID rather than a new one.
+type storageBlobBlock struct {
+	id     string
+	offset int64
+	length int64
+}
+
+// blockIDFromIndex derives a deterministic, ordering-preserving block ID from a
+// zero-padded sequential index, matching the scheme used by the high-level Azure
+// SDK uploaders.
+func blockIDFromIndex(index int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%064d", index)))
+}
+
+// splitIntoBlocks divides a file of fileSize bytes into fixed-size blocks (the
+// last of which may be shorter), each assigned a deterministic ID via
+// blockIDFromIndex.
+func splitIntoBlocks(fileSize int64, blockSize int64) ([]storageBlobBlock, []string) {
+	var blocks []storageBlobBlock
+	blockIDs := make([]string, 0)
+	for offset := int64(0); offset < fileSize; offset += blockSize {
+		length := blockSize
+		if offset+length > fileSize {
+			length = fileSize - offset
+		}
+
+		id := blockIDFromIndex(len(blocks))
+		blockIDs = append(blockIDs, id)
+		blocks = append(blocks, storageBlobBlock{
+			id:     id,
+			offset: offset,
+			length: length,
+		})
+	}
+
+	return blocks, blockIDs
+}
+
+// workerCountFor returns the number of worker goroutines to spawn for a given
+// `Parallelism` setting, flooring it at 1 so a zero-valued (unset) Parallelism
+// still spawns workers to drain the jobs channel instead of deadlocking.
+func workerCountFor(parallelism int) int {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	return parallelism * runtime.NumCPU()
+}
+
+func (sbu BlobUpload) uploadBlocks(ctx context.Context, file io.ReaderAt, fileSize int64, blockSize int64) ([]string, error) {
+	blocks, blockIDs := splitIntoBlocks(fileSize, blockSize)
+
+	workerCount := workerCountFor(sbu.Parallelism)
+	progress := newProgressTracker(fileSize, sbu.Progress)
+
+	jobs := make(chan storageBlobBlock, len(blocks))
+	errors := make(chan error, len(blocks))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(blocks))
+
+	for _, block := range blocks {
+		jobs <- block
+	}
+	close(jobs)
+
+	for i := 0; i < workerCount; i++ {
+		go sbu.blobBlockUploadWorker(ctx, blobBlockUploadContext{
+			file:     file,
+			blocks:   jobs,
+			errors:   errors,
+			wg:       wg,
+			progress: progress,
+		})
+	}
+
+	wg.Wait()
+
+	if len(errors) > 0 {
+		return nil, <-errors
+	}
+
+	return blockIDs, nil
+}
+
+type blobBlockUploadContext struct {
+	file     io.ReaderAt
+	blocks   chan storageBlobBlock
+	errors   chan error
+	wg       *sync.WaitGroup
+	progress *progressTracker
+}
+
+func (sbu BlobUpload) blobBlockUploadWorker(ctx context.Context, uploadCtx blobBlockUploadContext) {
+	for block := range uploadCtx.blocks {
+		if ctx.Err() != nil {
+			uploadCtx.errors <- ctx.Err()
+			uploadCtx.wg.Done()
+			continue
+		}
+
+		if err := sbu.putBlockWithRetry(ctx, uploadCtx.file, block, uploadCtx.progress); err != nil {
+			uploadCtx.errors <- err
+		}
+
+		uploadCtx.wg.Done()
+	}
+}
+
+// sleepOrCancel waits out d, returning early with ctx.Err() if ctx is cancelled
+// first - used so a cancelled run doesn't sleep out a retry backoff before
+// noticing it should stop.
+func sleepOrCancel(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// putBlockWithRetry stages a single block, making up to `Attempts` tries with
+// exponential backoff. The chunk is re-read from the `ReaderAt` at its offset on
+// every attempt rather than buffered, so a retry can't resend stale data.
+func (sbu BlobUpload) putBlockWithRetry(ctx context.Context, file io.ReaderAt, block storageBlobBlock, progress *progressTracker) error {
+	attempts := sbu.Attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if attempt > 0 {
+			if err := sleepOrCancel(ctx, time.Duration(1<<uint(attempt-1))*time.Second); err != nil {
+				return err
+			}
+		}
+
+		chunk := make([]byte, block.length)
+		if _, err := file.ReadAt(chunk, block.offset); err != nil && err != io.EOF {
+			lastErr = fmt.Errorf("Could not read chunk at %d: %s", block.offset, err)
+			continue
+		}
+
+		input := blobs.PutBlockInput{
+			BlockID: block.id,
+			Content: chunk,
+		}
+		sbu.applyEncryption(&input.EncryptionScope, &input.EncryptionKey, &input.EncryptionKeySHA256, &input.EncryptionAlgorithm)
+
+		if err := sbu.setContentChecksum(&input.ContentMD5, &input.ContentCRC64, chunk); err != nil {
+			lastErr = fmt.Errorf("Error computing checksum for block %q: %s", block.id, err)
+			continue
+		}
+
+		if _, err := sbu.Client.PutBlock(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, input); err != nil {
+			lastErr = fmt.Errorf("Error PutBlock %q at offset %d: %s", block.id, block.offset, err)
+			continue
+		}
+
+		progress.add(block.length)
+
+		return nil
+	}
+
+	return lastErr
+}
+
+// setContentChecksum computes the configured `VerifyContent` checksum of `chunk`
+// and populates whichever of `md5Field`/`crc64Field` applies, so the Azure service
+// can reject the write if the content was corrupted in transit.
+func (sbu BlobUpload) setContentChecksum(md5Field **string, crc64Field **string, chunk []byte) error {
+	switch strings.ToLower(sbu.VerifyContent) {
+	case "":
+		return nil
+	case verifyContentMD5:
+		sum := md5.Sum(chunk)
+		*md5Field = utils.String(base64.StdEncoding.EncodeToString(sum[:]))
+		return nil
+	case verifyContentCRC64:
+		sum := crc64.Checksum(chunk, crc64.MakeTable(0x9A6C9329AC4BC9B5))
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, sum)
+		*crc64Field = utils.String(base64.StdEncoding.EncodeToString(buf))
+		return nil
+	default:
+		return fmt.Errorf("Unsupported `VerifyContent` algorithm: %q", sbu.VerifyContent)
+	}
+}
+
+// md5OfReaderAt computes the MD5 of an entire `io.ReaderAt`, used as the whole
+// blob's Content-MD5 when its block list is committed. The stored value is not
+// read back or compared against the source after the upload.
+func md5OfReaderAt(file io.ReaderAt, size int64) (string, error) {
+	h := md5.New()
+	buf := make([]byte, defaultBlockSize)
+	for offset := int64(0); offset < size; offset += int64(len(buf)) {
+		length := int64(len(buf))
+		if offset+length > size {
+			length = size - offset
+		}
+
+		n, err := file.ReadAt(buf[:length], offset)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if _, err := h.Write(buf[:n]); err != nil {
+			return "", err
+		}
+	}
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
 func (sbu BlobUpload) createEmptyPageBlob(ctx context.Context) error {
 	if sbu.Size == 0 {
 		return fmt.Errorf("`size` cannot be zero for a page blob")
@@ -113,6 +554,11 @@ func (sbu BlobUpload) createEmptyPageBlob(ctx context.Context) error {
 		ContentType:            utils.String(sbu.ContentType),
 		MetaData:               sbu.MetaData,
 	}
+	if sbu.AccessTier != "" {
+		input.AccessTier = blobs.PremiumPageBlobAccessTier(sbu.AccessTier)
+	}
+	sbu.applyEncryption(&input.EncryptionScope, &input.EncryptionKey, &input.EncryptionKeySHA256, &input.EncryptionAlgorithm)
+
 	if _, err := sbu.Client.PutPageBlob(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, input); err != nil {
 		return fmt.Errorf("Error PutPageBlob: %s", err)
 	}
@@ -125,29 +571,31 @@ func (sbu BlobUpload) uploadPageBlob(ctx context.Context) error {
 		return fmt.Errorf("`size` cannot be set for an uploaded page blob")
 	}
 
-	// determine the details about the file
-	file, err := os.Open(sbu.Source)
+	// determine the details about the source
+	stream, fileSize, err := sbu.resolveSource().Open(ctx)
 	if err != nil {
-		return fmt.Errorf("Error opening source file for upload %q: %s", sbu.Source, err)
+		return fmt.Errorf("Error opening %q for upload: %s", sbu.sourceLabel(), err)
 	}
-	defer file.Close()
+	defer stream.Close()
 
 	// TODO: all of this ultimately can be moved into Giovanni
 
-	info, err := file.Stat()
+	file, fileSize, err := readerAtFor(stream, fileSize)
 	if err != nil {
-		return fmt.Errorf("Could not stat file %q: %s", file.Name(), err)
+		return fmt.Errorf("Error preparing %q for upload: %s", sbu.sourceLabel(), err)
 	}
 
-	fileSize := info.Size()
-
 	// first let's create a file of the specified file size
 	input := blobs.PutPageBlobInput{
 		BlobContentLengthBytes: fileSize,
 		ContentType:            utils.String(sbu.ContentType),
 		MetaData:               sbu.MetaData,
 	}
-	// TODO: access tiers?
+	if sbu.AccessTier != "" {
+		input.AccessTier = blobs.PremiumPageBlobAccessTier(sbu.AccessTier)
+	}
+	sbu.applyEncryption(&input.EncryptionScope, &input.EncryptionKey, &input.EncryptionKeySHA256, &input.EncryptionAlgorithm)
+
 	if _, err := sbu.Client.PutPageBlob(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, input); err != nil {
 		return fmt.Errorf("Error PutPageBlob: %s", err)
 	}
@@ -159,6 +607,98 @@ func (sbu BlobUpload) uploadPageBlob(ctx context.Context) error {
 	return nil
 }
 
+func (sbu BlobUpload) createEmptyAppendBlob(ctx context.Context) error {
+	input := blobs.PutAppendBlobInput{
+		ContentType: utils.String(sbu.ContentType),
+		MetaData:    sbu.MetaData,
+	}
+	if _, err := sbu.Client.PutAppendBlob(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, input); err != nil {
+		return fmt.Errorf("Error PutAppendBlob: %s", err)
+	}
+
+	return nil
+}
+
+const appendBlobBlockSize int64 = 4 * 1024 * 1024
+
+func (sbu BlobUpload) uploadAppendBlob(ctx context.Context) error {
+	stream, _, err := sbu.resolveSource().Open(ctx)
+	if err != nil {
+		return fmt.Errorf("Error opening %q: %s", sbu.sourceLabel(), err)
+	}
+	defer stream.Close()
+
+	// PutAppendBlob always (re)creates the blob empty, so it's only called when the
+	// blob doesn't already exist - otherwise a re-run would wipe out everything a
+	// previous run had already appended instead of resuming past it.
+	appendPosition := int64(0)
+	existing, err := sbu.Client.GetProperties(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, blobs.GetPropertiesInput{})
+	if err != nil {
+		if !utils.ResponseWasNotFound(existing.Response) {
+			return fmt.Errorf("Error checking for existing append blob %q: %s", sbu.BlobName, err)
+		}
+
+		input := blobs.PutAppendBlobInput{
+			ContentType: utils.String(sbu.ContentType),
+			MetaData:    sbu.MetaData,
+		}
+		if _, err := sbu.Client.PutAppendBlob(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, input); err != nil {
+			return fmt.Errorf("Error PutAppendBlob: %s", err)
+		}
+	} else {
+		appendPosition = existing.ContentLength
+
+		// skip the prefix of the source already committed on a previous run, so
+		// re-running the upload continues from where it left off rather than
+		// appending the whole source again on top of what's already there.
+		if appendPosition > 0 {
+			if _, err := stream.Seek(appendPosition, io.SeekStart); err != nil {
+				return fmt.Errorf("Error seeking %q to resume position %d: %s", sbu.sourceLabel(), appendPosition, err)
+			}
+		}
+	}
+
+	// append blobs are committed sequentially - each AppendBlock is pinned to the
+	// position it expects to land at (and, once known, the blob's maximum size) so
+	// that re-running an upload against a blob that's already got some blocks on it
+	// resumes past them rather than duplicating them.
+	blockCount := 0
+
+	buffer := make([]byte, appendBlobBlockSize)
+	for {
+		n, readErr := stream.Read(buffer)
+		if n > 0 {
+			if sbu.MaxBlockCount > 0 && blockCount >= sbu.MaxBlockCount {
+				return fmt.Errorf("Error appending to blob %q: exceeded MaxBlockCount of %d", sbu.BlobName, sbu.MaxBlockCount)
+			}
+
+			appendInput := blobs.AppendBlockInput{
+				Content:        buffer[:n],
+				AppendPosition: utils.Int64(appendPosition),
+			}
+			if sbu.MaxBlobSize > 0 {
+				appendInput.MaxSize = utils.Int64(sbu.MaxBlobSize)
+			}
+
+			if _, err := sbu.Client.AppendBlock(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, appendInput); err != nil {
+				return fmt.Errorf("Error AppendBlock at position %d for %q: %s", appendPosition, sbu.sourceLabel(), err)
+			}
+
+			appendPosition += int64(n)
+			blockCount++
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("Error reading %q at offset %d: %s", sbu.sourceLabel(), appendPosition, readErr)
+		}
+	}
+
+	return nil
+}
+
 // TODO: move below here into Giovanni
 
 type storageBlobPage struct {
@@ -167,12 +707,12 @@ type storageBlobPage struct {
 }
 
 func (sbu BlobUpload) pageUploadFromSource(ctx context.Context, file io.ReaderAt, fileSize int64) error {
-	workerCount := sbu.Parallelism * runtime.NumCPU()
+	workerCount := workerCountFor(sbu.Parallelism)
 
 	// first we chunk the file and assign them to 'pages'
 	pageList, err := sbu.storageBlobPageSplit(file, fileSize)
 	if err != nil {
-		return fmt.Errorf("Error splitting source file %q into pages: %s", sbu.Source, err)
+		return fmt.Errorf("Error splitting %q into pages: %s", sbu.sourceLabel(), err)
 	}
 
 	// finally we upload the contents of said file
@@ -188,19 +728,22 @@ func (sbu BlobUpload) pageUploadFromSource(ctx context.Context, file io.ReaderAt
 	}
 	close(pages)
 
+	progress := newProgressTracker(total, sbu.Progress)
+
 	for i := 0; i < workerCount; i++ {
 		go sbu.blobPageUploadWorker(ctx, blobPageUploadContext{
 			blobSize: fileSize,
 			pages:    pages,
 			errors:   errors,
 			wg:       wg,
+			progress: progress,
 		})
 	}
 
 	wg.Wait()
 
 	if len(errors) > 0 {
-		return fmt.Errorf("Error while uploading source file %q: %s", sbu.Source, <-errors)
+		return fmt.Errorf("Error while uploading %q: %s", sbu.sourceLabel(), <-errors)
 	}
 
 	return nil
@@ -269,21 +812,55 @@ type blobPageUploadContext struct {
 	pages    chan storageBlobPage
 	errors   chan error
 	wg       *sync.WaitGroup
+	progress *progressTracker
 }
 
 func (sbu BlobUpload) blobPageUploadWorker(ctx context.Context, uploadCtx blobPageUploadContext) {
 	for page := range uploadCtx.pages {
-		start := page.offset
-		end := page.offset + page.section.Size() - 1
-		if end > uploadCtx.blobSize-1 {
-			end = uploadCtx.blobSize - 1
+		if ctx.Err() != nil {
+			uploadCtx.errors <- ctx.Err()
+			uploadCtx.wg.Done()
+			continue
+		}
+
+		if err := sbu.putPageWithRetry(ctx, uploadCtx.blobSize, page, uploadCtx.progress); err != nil {
+			uploadCtx.errors <- err
+		}
+
+		uploadCtx.wg.Done()
+	}
+}
+
+// putPageWithRetry writes a single page range, making up to `Attempts` tries
+// (with exponential backoff) and re-reading the page's section on every attempt.
+func (sbu BlobUpload) putPageWithRetry(ctx context.Context, blobSize int64, page storageBlobPage, progress *progressTracker) error {
+	start := page.offset
+	end := page.offset + page.section.Size() - 1
+	if end > blobSize-1 {
+		end = blobSize - 1
+	}
+	size := end - start + 1
+
+	attempts := sbu.Attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if attempt > 0 {
+			if err := sleepOrCancel(ctx, time.Duration(1<<uint(attempt-1))*time.Second); err != nil {
+				return err
+			}
 		}
-		size := end - start + 1
 
 		chunk := make([]byte, size)
-		if _, err := page.section.Read(chunk); err != nil && err != io.EOF {
-			uploadCtx.errors <- fmt.Errorf("Error reading source file %q at offset %d: %s", sbu.Source, page.offset, err)
-			uploadCtx.wg.Done()
+		if _, err := page.section.ReadAt(chunk, 0); err != nil && err != io.EOF {
+			lastErr = fmt.Errorf("Error reading %q at offset %d: %s", sbu.sourceLabel(), page.offset, err)
 			continue
 		}
 
@@ -292,13 +869,22 @@ func (sbu BlobUpload) blobPageUploadWorker(ctx context.Context, uploadCtx blobPa
 			EndByte:   end,
 			Content:   chunk,
 		}
+		sbu.applyEncryption(&input.EncryptionScope, &input.EncryptionKey, &input.EncryptionKeySHA256, &input.EncryptionAlgorithm)
+
+		if err := sbu.setContentChecksum(&input.ContentMD5, &input.ContentCRC64, chunk); err != nil {
+			lastErr = fmt.Errorf("Error computing checksum for page at offset %d: %s", page.offset, err)
+			continue
+		}
 
 		if _, err := sbu.Client.PutPageUpdate(ctx, sbu.AccountName, sbu.ContainerName, sbu.BlobName, input); err != nil {
-			uploadCtx.errors <- fmt.Errorf("Error writing page at offset %d for file %q: %s", page.offset, sbu.Source, err)
-			uploadCtx.wg.Done()
+			lastErr = fmt.Errorf("Error writing page at offset %d for %q: %s", page.offset, sbu.sourceLabel(), err)
 			continue
 		}
 
-		uploadCtx.wg.Done()
+		progress.add(size)
+
+		return nil
 	}
+
+	return lastErr
 }
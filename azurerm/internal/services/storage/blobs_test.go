@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitIntoBlocks(t *testing.T) {
+	cases := []struct {
+		name      string
+		fileSize  int64
+		blockSize int64
+		wantCount int
+		wantLast  int64
+	}{
+		{name: "empty file", fileSize: 0, blockSize: 10, wantCount: 0},
+		{name: "exact multiple", fileSize: 20, blockSize: 10, wantCount: 2, wantLast: 10},
+		{name: "short final block", fileSize: 25, blockSize: 10, wantCount: 3, wantLast: 5},
+		{name: "smaller than one block", fileSize: 3, blockSize: 10, wantCount: 1, wantLast: 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			blocks, blockIDs := splitIntoBlocks(tc.fileSize, tc.blockSize)
+
+			if len(blocks) != tc.wantCount {
+				t.Fatalf("got %d blocks, want %d", len(blocks), tc.wantCount)
+			}
+			if len(blockIDs) != tc.wantCount {
+				t.Fatalf("got %d block IDs, want %d", len(blockIDs), tc.wantCount)
+			}
+			if tc.wantCount == 0 {
+				return
+			}
+
+			var total int64
+			for i, block := range blocks {
+				if block.offset != total {
+					t.Fatalf("block %d offset = %d, want %d", i, block.offset, total)
+				}
+				if block.id != blockIDs[i] {
+					t.Fatalf("block %d id = %q, want %q", i, block.id, blockIDs[i])
+				}
+				total += block.length
+			}
+			if total != tc.fileSize {
+				t.Fatalf("blocks cover %d bytes, want %d", total, tc.fileSize)
+			}
+
+			last := blocks[len(blocks)-1]
+			if last.length != tc.wantLast {
+				t.Fatalf("last block length = %d, want %d", last.length, tc.wantLast)
+			}
+		})
+	}
+}
+
+func TestSplitIntoBlocksDeterministicIDs(t *testing.T) {
+	blocksA, idsA := splitIntoBlocks(100, 10)
+	blocksB, idsB := splitIntoBlocks(100, 10)
+
+	for i := range blocksA {
+		if blocksA[i].id != blocksB[i].id {
+			t.Fatalf("block %d id not deterministic: %q vs %q", i, blocksA[i].id, blocksB[i].id)
+		}
+	}
+	for i := range idsA {
+		if idsA[i] != idsB[i] {
+			t.Fatalf("block ID %d not deterministic: %q vs %q", i, idsA[i], idsB[i])
+		}
+	}
+}
+
+func TestWorkerCountForFloorsAtParallelism1(t *testing.T) {
+	cases := []int{-5, -1, 0}
+
+	for _, parallelism := range cases {
+		if got := workerCountFor(parallelism); got < 1 {
+			t.Fatalf("workerCountFor(%d) = %d, want at least 1 (would deadlock wg.Wait with a non-empty jobs channel)", parallelism, got)
+		}
+	}
+}
+
+func TestWorkerCountForScalesWithParallelism(t *testing.T) {
+	one := workerCountFor(1)
+	two := workerCountFor(2)
+
+	if two != one*2 {
+		t.Fatalf("workerCountFor(2) = %d, want %d (2x workerCountFor(1) = %d)", two, one*2, one)
+	}
+}
+
+func TestStorageBlobPageSplitSkipsEmptyPages(t *testing.T) {
+	// one non-empty page, one empty page, one non-empty page
+	content := make([]byte, minPageSize*3)
+	for i := int64(0); i < minPageSize; i++ {
+		content[i] = 0xAA
+		content[minPageSize*2+i] = 0xBB
+	}
+
+	file := bytes.NewReader(content)
+	sbu := BlobUpload{}
+
+	pages, err := sbu.storageBlobPageSplit(file, int64(len(content)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(pages) != 2 {
+		t.Fatalf("got %d pages, want 2 (the all-zero middle page should be skipped)", len(pages))
+	}
+
+	if pages[0].offset != 0 {
+		t.Fatalf("first page offset = %d, want 0", pages[0].offset)
+	}
+	if pages[1].offset != minPageSize*2 {
+		t.Fatalf("second page offset = %d, want %d", pages[1].offset, minPageSize*2)
+	}
+}
+
+func TestStorageBlobPageSplitMergesAdjacentNonEmptyPages(t *testing.T) {
+	content := make([]byte, minPageSize*2)
+	for i := range content {
+		content[i] = 0xFF
+	}
+
+	file := bytes.NewReader(content)
+	sbu := BlobUpload{}
+
+	pages, err := sbu.storageBlobPageSplit(file, int64(len(content)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(pages) != 1 {
+		t.Fatalf("got %d pages, want 1 (adjacent non-empty ranges should merge)", len(pages))
+	}
+	if pages[0].section.Size() != int64(len(content)) {
+		t.Fatalf("merged page size = %d, want %d", pages[0].section.Size(), len(content))
+	}
+}
+
+func TestStorageBlobPageSplitAllEmpty(t *testing.T) {
+	content := make([]byte, minPageSize*2)
+
+	file := bytes.NewReader(content)
+	sbu := BlobUpload{}
+
+	pages, err := sbu.storageBlobPageSplit(file, int64(len(content)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(pages) != 0 {
+		t.Fatalf("got %d pages, want 0 for an all-zero blob", len(pages))
+	}
+}